@@ -14,23 +14,38 @@ const required = "required"
 
 // Provider represents a terraform provider block.
 type Provider struct {
-	Name          string
-	Documentation string
+	Name          string `json:"name" yaml:"name"`
+	Alias         string `json:"alias,omitempty" yaml:"alias,omitempty"`
+	Source        string `json:"source,omitempty" yaml:"source,omitempty"`
+	Version       string `json:"version,omitempty" yaml:"version,omitempty"`
+	Documentation string `json:"documentation" yaml:"documentation"`
 }
 
 // Resource represents a terraform resource block.
 type Resource struct {
-	Name          string
-	Type          string
-	Documentation string
+	Name          string   `json:"name" yaml:"name"`
+	Type          string   `json:"type" yaml:"type"`
+	Documentation string   `json:"documentation" yaml:"documentation"`
+	Instances     int      `json:"instances,omitempty" yaml:"instances,omitempty"`
+	Addresses     []string `json:"addresses,omitempty" yaml:"addresses,omitempty"`
 }
 
 // Input represents a terraform input variable.
 type Input struct {
-	Name        string
-	Description string
-	Default     *Value
-	Type        string
+	Name        string       `json:"name" yaml:"name"`
+	Description string       `json:"description,omitempty" yaml:"description,omitempty"`
+	Default     *Value       `json:"default,omitempty" yaml:"default,omitempty"`
+	Type        string       `json:"type" yaml:"type"`
+	Validations []Validation `json:"validations,omitempty" yaml:"validations,omitempty"`
+	Sensitive   bool         `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	Nullable    bool         `json:"nullable,omitempty" yaml:"nullable,omitempty"`
+	Position    int          `json:"-" yaml:"-"`
+}
+
+// Validation represents a `validation` block on an input variable.
+type Validation struct {
+	Condition    string `json:"condition" yaml:"condition"`
+	ErrorMessage string `json:"error_message" yaml:"error_message"`
 }
 
 // Value returns the default value as a string.
@@ -51,24 +66,27 @@ func (i *Input) Value() string {
 
 // Value represents a terraform value.
 type Value struct {
-	Type    string
-	Literal string
+	Type    string `json:"type" yaml:"type"`
+	Literal string `json:"literal,omitempty" yaml:"literal,omitempty"`
 }
 
 // Output represents a terraform output.
 type Output struct {
-	Name        string
-	Description string
+	Name        string      `json:"name" yaml:"name"`
+	Description string      `json:"description,omitempty" yaml:"description,omitempty"`
+	Value       interface{} `json:"value,omitempty" yaml:"value,omitempty"`
+	Sensitive   bool        `json:"sensitive,omitempty" yaml:"sensitive,omitempty"`
+	Position    int         `json:"-" yaml:"-"`
 }
 
 // Doc represents a terraform module doc.
 type Doc struct {
-	Version   string
-	Comment   string
-	Providers []Provider
-	Resources []Resource
-	Inputs    []Input
-	Outputs   []Output
+	Version   string     `json:"version,omitempty" yaml:"version,omitempty"`
+	Comment   string     `json:"comment,omitempty" yaml:"comment,omitempty"`
+	Providers []Provider `json:"providers,omitempty" yaml:"providers,omitempty"`
+	Resources []Resource `json:"resources,omitempty" yaml:"resources,omitempty"`
+	Inputs    []Input    `json:"inputs,omitempty" yaml:"inputs,omitempty"`
+	Outputs   []Output   `json:"outputs,omitempty" yaml:"outputs,omitempty"`
 }
 
 type inputsByName []Input
@@ -101,9 +119,76 @@ func (a inputsByRequired) Less(i, j int) bool {
 	}
 }
 
+type inputsByType []Input
+
+func (a inputsByType) Len() int      { return len(a) }
+func (a inputsByType) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a inputsByType) Less(i, j int) bool {
+	if a[i].Type == a[j].Type {
+		return a[i].Name < a[j].Name
+	}
+	return a[i].Type < a[j].Type
+}
+
+type inputsByPosition []Input
+
+func (a inputsByPosition) Len() int      { return len(a) }
+func (a inputsByPosition) Swap(i, j int) { a[i], a[j] = a[j], a[i] }
+func (a inputsByPosition) Less(i, j int) bool {
+	return a[i].Position < a[j].Position
+}
+
+// SortMode selects how Create, CreateHCL2, CreateDir and CreateTree order
+// doc.Inputs before returning.
+//
+// There is no cmd/main package in this repository to wire a --sort flag
+// into, so callers currently select a SortMode in Go code rather than on
+// the command line; exposing it via a CLI flag (and doing the same for
+// Format, see marshal.go) is out of scope until such an entrypoint exists.
+type SortMode int
+
+const (
+	// SortNone preserves the order inputs were declared in, which
+	// matters when an author has deliberately grouped related
+	// variables together.
+	SortNone SortMode = iota
+	// SortByName sorts inputs alphabetically by name. A SortMode value
+	// outside the ones listed here also sorts by name; the zero value
+	// is SortNone.
+	SortByName
+	// SortByRequired sorts required inputs (those without a default)
+	// ahead of optional ones, then alphabetically by name.
+	SortByRequired
+	// SortByType sorts inputs by their declared type, then
+	// alphabetically by name.
+	SortByType
+	// SortByPosition sorts inputs by their source line, i.e. the
+	// order they appear in their file. This differs from SortNone
+	// only when inputs are gathered from more than one file; the
+	// position holds only the line number, so inputs from different
+	// files are interleaved by line.
+	SortByPosition
+)
+
+// sortInputs orders inputs in place according to mode.
+func sortInputs(inputs []Input, mode SortMode) {
+	switch mode {
+	case SortNone:
+		return
+	case SortByRequired:
+		sort.Sort(inputsByRequired(inputs))
+	case SortByType:
+		sort.Sort(inputsByType(inputs))
+	case SortByPosition:
+		sort.Sort(inputsByPosition(inputs))
+	default:
+		sort.Sort(inputsByName(inputs))
+	}
+}
+
 // Create creates a new *Doc from the supplied map
 // of filenames and *ast.File.
-func Create(files map[string]*ast.File, sortByRequired bool) *Doc {
+func Create(files map[string]*ast.File, mode SortMode) *Doc {
 	doc := new(Doc)
 
 	for name, f := range files {
@@ -114,7 +199,7 @@ func Create(files map[string]*ast.File, sortByRequired bool) *Doc {
 			doc.Version = required_version
 		}
 
-		doc.Providers = append(doc.Providers, providers(list)...)
+		doc.Providers = append(doc.Providers, providers(list, requiredProviders(list))...)
 		doc.Resources = append(doc.Resources, resources(list)...)
 		doc.Inputs = append(doc.Inputs, inputs(list)...)
 		doc.Outputs = append(doc.Outputs, outputs(list)...)
@@ -127,13 +212,7 @@ func Create(files map[string]*ast.File, sortByRequired bool) *Doc {
 		}
 	}
 
-    	switch {
-    	case sortByRequired:
-    		sort.Sort(inputsByRequired(doc.Inputs))
-    	default:
-    		sort.Sort(inputsByName(doc.Inputs))
-    	}
-	sort.Sort(inputsByName(doc.Inputs))
+	sortInputs(doc.Inputs, mode)
 	sort.Sort(outputsByName(doc.Outputs))
 	return doc
 }
@@ -156,21 +235,97 @@ func version(list *ast.ObjectList) string {
 	return ret
 }
 
-// Providers returns all providers from 'list' along with links
-// to their Terraform documentation.
-func providers(list *ast.ObjectList) []Provider {
+// Providers returns all providers from 'list' along with links to their
+// Terraform documentation. required gives the source/version constraints
+// declared in the module's `terraform { required_providers { ... } }`
+// block, keyed by provider name, which are merged into matching provider
+// blocks (or emitted on their own when a provider has no explicit block).
+func providers(list *ast.ObjectList, required map[string]Provider) []Provider {
 	var ret []Provider
+	seen := map[string]bool{}
 
 	for _, item := range list.Items {
 		if is(item, "provider") {
 			name := item.Keys[1].Token.Text
 			name = strings.Trim(name, "\"")
-			link := fmt.Sprintf("https://www.terraform.io/docs/providers/%s", name)
 
-			ret = append(ret, Provider{
-				Name:          name,
-				Documentation: link,
-			})
+			items := item.Val.(*ast.ObjectType).List.Items
+			var alias string
+			if v := get(items, "alias"); v != nil {
+				alias = v.Literal
+			}
+
+			p := required[name]
+			p.Name = name
+			p.Alias = alias
+			p.Documentation = providerLink(name, p.Source)
+
+			ret = append(ret, p)
+			seen[name] = true
+		}
+	}
+
+	for name, p := range required {
+		if seen[name] {
+			continue
+		}
+
+		p.Name = name
+		p.Documentation = providerLink(name, p.Source)
+		ret = append(ret, p)
+	}
+
+	return ret
+}
+
+// providerLink returns the documentation URL for a provider. Providers
+// declared with a `source` in required_providers point at the registry;
+// everything else falls back to the legacy providers path.
+func providerLink(name, source string) string {
+	if source != "" {
+		return fmt.Sprintf("https://registry.terraform.io/providers/%s", source)
+	}
+
+	return fmt.Sprintf("https://www.terraform.io/docs/providers/%s", name)
+}
+
+// requiredProviders parses the `terraform { required_providers { ... } }`
+// block from 'list', returning the declared source/version constraints
+// keyed by provider name. Both the legacy string form
+// (`aws = "~> 4.0"`) and the object form
+// (`aws = { source = "hashicorp/aws", version = "~> 4.0" }`) are
+// supported.
+func requiredProviders(list *ast.ObjectList) map[string]Provider {
+	ret := map[string]Provider{}
+
+	for _, item := range list.Items {
+		if !is(item, "terraform") {
+			continue
+		}
+
+		for _, sub := range item.Val.(*ast.ObjectType).List.Items {
+			if !is(sub, "required_providers") {
+				continue
+			}
+
+			for _, decl := range sub.Val.(*ast.ObjectType).List.Items {
+				name := decl.Keys[0].Token.Text
+				name = strings.Trim(name, "\"")
+
+				switch v := decl.Val.(type) {
+				case *ast.LiteralType:
+					ret[name] = Provider{Version: strings.Trim(v.Token.Text, "\"")}
+				case *ast.ObjectType:
+					var p Provider
+					if src := get(v.List.Items, "source"); src != nil {
+						p.Source = src.Literal
+					}
+					if ver := get(v.List.Items, "version"); ver != nil {
+						p.Version = ver.Literal
+					}
+					ret[name] = p
+				}
+			}
 		}
 	}
 
@@ -240,6 +395,16 @@ func inputs(list *ast.ObjectList) []Input {
 				Description: desc,
 				Default:     def,
 				Type:        itemType,
+				// Validations is left unset on this path: a
+				// `validation { condition = ... }` block's
+				// condition is always an expression (e.g.
+				// `var.age >= 0`), which isn't valid HCL1
+				// syntax. Validation blocks are HCL2-only
+				// (0.13+) and are populated by inputs2 in
+				// hcl2.go instead.
+				Sensitive: boolValue(get(items, "sensitive")),
+				Nullable:  boolValue(get(items, "nullable")),
+				Position:  item.Pos().Line,
 			})
 		}
 	}
@@ -269,6 +434,7 @@ func outputs(list *ast.ObjectList) []Output {
 			ret = append(ret, Output{
 				Name:        name,
 				Description: strings.TrimSpace(desc),
+				Position:    item.Pos().Line,
 			})
 		}
 	}
@@ -309,6 +475,11 @@ func get(items []*ast.ObjectItem, key string) *Value {
 	return nil
 }
 
+// boolValue reports whether v represents the literal `true`.
+func boolValue(v *Value) bool {
+	return v != nil && v.Literal == "true"
+}
+
 // description returns a description from items or an empty string.
 func description(items []*ast.ObjectItem) string {
 	if v := get(items, "description"); v != nil {
@@ -318,6 +489,25 @@ func description(items []*ast.ObjectItem) string {
 	return ""
 }
 
+// moduleSources returns the `source` attribute of every `module` block in
+// 'list', e.g. the "./child" in `module "x" { source = "./child" }`.
+func moduleSources(list *ast.ObjectList) []string {
+	var ret []string
+
+	for _, item := range list.Items {
+		if !is(item, "module") {
+			continue
+		}
+
+		items := item.Val.(*ast.ObjectType).List.Items
+		if src := get(items, "source"); src != nil {
+			ret = append(ret, src.Literal)
+		}
+	}
+
+	return ret
+}
+
 // Is returns true if `item` is of `kind`.
 func is(item *ast.ObjectItem, kind string) bool {
 	if len(item.Keys) > 0 {
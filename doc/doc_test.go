@@ -0,0 +1,72 @@
+package doc
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestCreateSortModes(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "main.tf"), `
+variable "alpha" {
+  type    = "string"
+  default = "a"
+}
+
+variable "zulu" {
+  type = "list"
+}
+
+variable "mid" {
+  type    = "bool"
+  default = "true"
+}
+
+variable "beta" {
+  type = "map"
+}
+`)
+
+	files, err := parseHCL1Dir(dir)
+	if err != nil {
+		t.Fatalf("parseHCL1Dir returned error: %s", err)
+	}
+
+	inputNames := func(inputs []Input) []string {
+		ret := make([]string, len(inputs))
+		for i, in := range inputs {
+			ret[i] = in.Name
+		}
+		return ret
+	}
+
+	cases := []struct {
+		name string
+		mode SortMode
+		want []string
+	}{
+		{"name", SortByName, []string{"alpha", "beta", "mid", "zulu"}},
+		// Required inputs (no default: zulu, beta) sort ahead of
+		// optional ones (alpha, mid), each group alphabetically. This
+		// differs from SortByName, which is exactly what the old dead
+		// `switch { case sortByRequired: ...}; sort.Sort(inputsByName(...))`
+		// bug would have gotten wrong: it always produced the
+		// SortByName order regardless of this mode.
+		{"required", SortByRequired, []string{"beta", "zulu", "alpha", "mid"}},
+		{"type", SortByType, []string{"mid", "zulu", "beta", "alpha"}},
+		{"position", SortByPosition, []string{"alpha", "zulu", "mid", "beta"}},
+		{"none", SortNone, []string{"alpha", "zulu", "mid", "beta"}},
+	}
+
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			doc := Create(files, c.mode)
+			got := inputNames(doc.Inputs)
+			if !reflect.DeepEqual(got, c.want) {
+				t.Errorf("expected order %v, got %v", c.want, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,481 @@
+package doc
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	hcl1 "github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/v2"
+	"github.com/hashicorp/hcl/v2/ext/typeexpr"
+	"github.com/hashicorp/hcl/v2/hclparse"
+	"github.com/hashicorp/hcl/v2/hclsyntax"
+	"github.com/zclconf/go-cty/cty"
+)
+
+// Mode selects which HCL grammar a module's source files are written in.
+type Mode int
+
+const (
+	// ModeHCL1 is the legacy pre-0.12 grammar handled by Create.
+	ModeHCL1 Mode = iota
+	// ModeHCL2 is the Terraform 0.12+ grammar handled by CreateHCL2.
+	ModeHCL2
+)
+
+// DetectMode inspects the *.tf files in dir and reports whether they are
+// written in the legacy HCL1 grammar or the HCL2 grammar introduced in
+// Terraform 0.12. A module is treated as HCL2 as soon as one of its files
+// fails to parse under HCL1, since HCL2-only constructs such as typed
+// variables and `object({...})` are not valid HCL1.
+func DetectMode(dir string) Mode {
+	files, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil || len(files) == 0 {
+		return ModeHCL1
+	}
+
+	for _, name := range files {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			continue
+		}
+
+		if _, err := hcl1.ParseBytes(src); err != nil {
+			return ModeHCL2
+		}
+	}
+
+	return ModeHCL1
+}
+
+// CreateDir creates a new *Doc for the module rooted at dir, sniffing
+// whether it is written in HCL1 or HCL2 with DetectMode and dispatching
+// to Create or CreateHCL2 accordingly. This is the entry point callers
+// should use when they only have a directory on disk rather than
+// pre-parsed HCL1 ASTs.
+func CreateDir(dir string, mode SortMode) (*Doc, error) {
+	if DetectMode(dir) == ModeHCL2 {
+		return CreateHCL2(dir, mode)
+	}
+
+	files, err := parseHCL1Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	return Create(files, mode), nil
+}
+
+// parseHCL1Dir parses every *.tf file in dir with the legacy HCL1 parser,
+// matching the map[string]*ast.File shape Create expects.
+func parseHCL1Dir(dir string) (map[string]*ast.File, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	files := make(map[string]*ast.File, len(names))
+	for _, name := range names {
+		src, err := ioutil.ReadFile(name)
+		if err != nil {
+			return nil, err
+		}
+
+		f, err := hcl1.ParseBytes(src)
+		if err != nil {
+			return nil, fmt.Errorf("parsing %s: %s", name, err)
+		}
+
+		files[name] = f
+	}
+
+	return files, nil
+}
+
+// parseHCL2Dir parses every *.tf file in dir with the native HCL2 syntax,
+// returning the parsed bodies keyed by filename.
+func parseHCL2Dir(dir string) (map[string]*hclsyntax.Body, error) {
+	names, err := filepath.Glob(filepath.Join(dir, "*.tf"))
+	if err != nil {
+		return nil, err
+	}
+
+	parser := hclparse.NewParser()
+	bodies := make(map[string]*hclsyntax.Body, len(names))
+
+	for _, name := range names {
+		f, diags := parser.ParseHCLFile(name)
+		if diags.HasErrors() {
+			return nil, diags
+		}
+
+		bodies[name] = f.Body.(*hclsyntax.Body)
+	}
+
+	return bodies, nil
+}
+
+// moduleSourcesDir returns the source address of every `module` block
+// declared in dir, sniffing the grammar the same way CreateDir does.
+func moduleSourcesDir(dir string) ([]string, error) {
+	if DetectMode(dir) == ModeHCL2 {
+		bodies, err := parseHCL2Dir(dir)
+		if err != nil {
+			return nil, err
+		}
+
+		var ret []string
+		for _, body := range bodies {
+			ret = append(ret, moduleSources2(body)...)
+		}
+		return ret, nil
+	}
+
+	files, err := parseHCL1Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var ret []string
+	for _, f := range files {
+		ret = append(ret, moduleSources(f.Node.(*ast.ObjectList))...)
+	}
+
+	return ret, nil
+}
+
+// CreateHCL2 creates a new *Doc from the Terraform 0.12+ module rooted at
+// dir, parsing its *.tf files directly against hcl/v2's native syntax
+// (hclsyntax) and walking the resulting blocks for terraform{},
+// variable, output, resource and provider declarations.
+func CreateHCL2(dir string, mode SortMode) (*Doc, error) {
+	bodies, err := parseHCL2Dir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := new(Doc)
+
+	for _, body := range bodies {
+		required, version := terraformBlock2(body)
+		if version != "" {
+			doc.Version = version
+		}
+
+		doc.Providers = append(doc.Providers, providers2(body, required)...)
+		doc.Resources = append(doc.Resources, resources2(body)...)
+		doc.Inputs = append(doc.Inputs, inputs2(body)...)
+		doc.Outputs = append(doc.Outputs, outputs2(body)...)
+	}
+
+	sortInputs(doc.Inputs, mode)
+	sort.Sort(outputsByName(doc.Outputs))
+
+	return doc, nil
+}
+
+// terraformBlock2 reads the `terraform { required_version = "...",
+// required_providers { ... } }` block from body, returning the declared
+// provider source/version constraints keyed by provider name alongside
+// the required_version string.
+func terraformBlock2(body *hclsyntax.Body) (required map[string]Provider, version string) {
+	required = map[string]Provider{}
+
+	for _, block := range body.Blocks {
+		if block.Type != "terraform" {
+			continue
+		}
+
+		if attr, ok := block.Body.Attributes["required_version"]; ok {
+			version = literalString(attr.Expr)
+		}
+
+		for _, sub := range block.Body.Blocks {
+			if sub.Type != "required_providers" {
+				continue
+			}
+
+			for name, attr := range sub.Body.Attributes {
+				required[name] = requiredProvider2(attr.Expr)
+			}
+		}
+	}
+
+	return required, version
+}
+
+// requiredProvider2 evaluates a required_providers entry, supporting
+// both the legacy string form (`aws = "~> 4.0"`) and the object form
+// (`aws = { source = "hashicorp/aws", version = "~> 4.0" }`).
+func requiredProvider2(expr hcl.Expression) Provider {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || v.IsNull() {
+		return Provider{}
+	}
+
+	if v.Type() == cty.String {
+		return Provider{Version: v.AsString()}
+	}
+
+	var p Provider
+	if v.Type().IsObjectType() {
+		if v.Type().HasAttribute("source") {
+			p.Source = v.GetAttr("source").AsString()
+		}
+		if v.Type().HasAttribute("version") {
+			p.Version = v.GetAttr("version").AsString()
+		}
+	}
+
+	return p
+}
+
+// providers2 returns all provider blocks declared in body, enriched with
+// the source/version constraints from required_providers. A provider
+// declared only in required_providers (with no explicit provider block)
+// is still emitted on its own.
+func providers2(body *hclsyntax.Body, required map[string]Provider) []Provider {
+	var ret []Provider
+	seen := map[string]bool{}
+
+	for _, block := range body.Blocks {
+		if block.Type != "provider" {
+			continue
+		}
+
+		name := block.Labels[0]
+
+		p := required[name]
+		p.Name = name
+		if attr, ok := block.Body.Attributes["alias"]; ok {
+			p.Alias = literalString(attr.Expr)
+		}
+		p.Documentation = providerLink(name, p.Source)
+
+		ret = append(ret, p)
+		seen[name] = true
+	}
+
+	for name, p := range required {
+		if seen[name] {
+			continue
+		}
+
+		p.Name = name
+		p.Documentation = providerLink(name, p.Source)
+		ret = append(ret, p)
+	}
+
+	return ret
+}
+
+// resources2 returns all managed resources declared in body.
+func resources2(body *hclsyntax.Body) []Resource {
+	var ret []Resource
+
+	for _, block := range body.Blocks {
+		if block.Type != "resource" {
+			continue
+		}
+
+		resourceType := block.Labels[0]
+		name := block.Labels[1]
+
+		resourceTypes := strings.SplitN(resourceType, "_", 2)
+		namespace := resourceTypes[0]
+		item := resourceType
+		if len(resourceTypes) == 2 {
+			item = resourceTypes[1]
+		}
+		link := fmt.Sprintf("https://www.terraform.io/docs/providers/%s/r/%s.html", namespace, item)
+
+		ret = append(ret, Resource{
+			Name:          name,
+			Type:          resourceType,
+			Documentation: link,
+		})
+	}
+
+	return ret
+}
+
+// inputs2 returns all variables declared in body, recording their
+// (possibly complex) type expression as the Input.Type string and
+// threading through validation blocks and the sensitive/nullable
+// meta-arguments.
+func inputs2(body *hclsyntax.Body) []Input {
+	var ret []Input
+
+	for _, block := range body.Blocks {
+		if block.Type != "variable" {
+			continue
+		}
+
+		name := block.Labels[0]
+
+		var desc string
+		if attr, ok := block.Body.Attributes["description"]; ok {
+			desc = literalString(attr.Expr)
+		}
+
+		itemType := "string"
+		if attr, ok := block.Body.Attributes["type"]; ok {
+			if ty, diags := typeexpr.Type(attr.Expr); !diags.HasErrors() {
+				itemType = typeexpr.TypeString(ty)
+			}
+		}
+
+		var def *Value
+		if attr, ok := block.Body.Attributes["default"]; ok {
+			def = ctyValue2(attr.Expr)
+		}
+
+		var sensitive, nullable bool
+		if attr, ok := block.Body.Attributes["sensitive"]; ok {
+			sensitive = literalBool(attr.Expr)
+		}
+		if attr, ok := block.Body.Attributes["nullable"]; ok {
+			nullable = literalBool(attr.Expr)
+		}
+
+		var vals []Validation
+		for _, sub := range block.Body.Blocks {
+			if sub.Type != "validation" {
+				continue
+			}
+
+			var v Validation
+			if attr, ok := sub.Body.Attributes["condition"]; ok {
+				v.Condition = exprSource(attr.Expr)
+			}
+			if attr, ok := sub.Body.Attributes["error_message"]; ok {
+				v.ErrorMessage = literalString(attr.Expr)
+			}
+			vals = append(vals, v)
+		}
+
+		ret = append(ret, Input{
+			Name:        name,
+			Description: desc,
+			Default:     def,
+			Type:        itemType,
+			Validations: vals,
+			Sensitive:   sensitive,
+			Nullable:    nullable,
+			Position:    block.TypeRange.Start.Line,
+		})
+	}
+
+	return ret
+}
+
+// outputs2 returns all outputs declared in body.
+func outputs2(body *hclsyntax.Body) []Output {
+	var ret []Output
+
+	for _, block := range body.Blocks {
+		if block.Type != "output" {
+			continue
+		}
+
+		name := block.Labels[0]
+
+		var desc string
+		if attr, ok := block.Body.Attributes["description"]; ok {
+			desc = literalString(attr.Expr)
+		}
+
+		ret = append(ret, Output{
+			Name:        name,
+			Description: strings.TrimSpace(desc),
+			Position:    block.TypeRange.Start.Line,
+		})
+	}
+
+	return ret
+}
+
+// moduleSources2 returns the source address of every `module` block
+// declared in body.
+func moduleSources2(body *hclsyntax.Body) []string {
+	var ret []string
+
+	for _, block := range body.Blocks {
+		if block.Type != "module" {
+			continue
+		}
+
+		if attr, ok := block.Body.Attributes["source"]; ok {
+			ret = append(ret, literalString(attr.Expr))
+		}
+	}
+
+	return ret
+}
+
+// literalString evaluates expr as a constant and returns it as a string,
+// or "" if it isn't a known, constant string (e.g. it references a
+// variable).
+func literalString(expr hcl.Expression) string {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || v.IsNull() || v.Type() != cty.String {
+		return ""
+	}
+
+	return v.AsString()
+}
+
+// literalBool evaluates expr as a constant and returns it as a bool, or
+// false if it isn't a known, constant bool.
+func literalBool(expr hcl.Expression) bool {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || v.IsNull() || v.Type() != cty.Bool {
+		return false
+	}
+
+	return v.True()
+}
+
+// exprSource returns the raw source text an hcl.Expression was parsed
+// from, e.g. "var.age >= 0", so validation conditions can be shown
+// verbatim in the generated docs.
+func exprSource(expr hcl.Expression) string {
+	rng := expr.Range()
+
+	src, err := ioutil.ReadFile(rng.Filename)
+	if err != nil {
+		return ""
+	}
+
+	return string(rng.SliceBytes(src))
+}
+
+// ctyValue2 evaluates a variable's default expression as a constant and
+// converts it into the doc package's own Value representation,
+// mirroring the "string"/"map"/"list" categories the HCL1 printers
+// already understand. Non-constant or unknown defaults are dropped.
+func ctyValue2(expr hcl.Expression) *Value {
+	v, diags := expr.Value(nil)
+	if diags.HasErrors() || v.IsNull() || !v.IsKnown() {
+		return nil
+	}
+
+	t := v.Type()
+	switch {
+	case t == cty.String:
+		return &Value{Type: "string", Literal: v.AsString()}
+	case t == cty.Number:
+		return &Value{Type: "string", Literal: v.AsBigFloat().String()}
+	case t == cty.Bool:
+		return &Value{Type: "string", Literal: fmt.Sprintf("%t", v.True())}
+	case t.IsListType(), t.IsSetType(), t.IsTupleType():
+		return &Value{Type: "list"}
+	case t.IsMapType(), t.IsObjectType():
+		return &Value{Type: "map"}
+	default:
+		return nil
+	}
+}
@@ -0,0 +1,146 @@
+package doc
+
+import (
+	"testing"
+)
+
+func TestCreateHCL2(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, dir+"/main.tf", `
+terraform {
+  required_version = ">= 0.12"
+
+  required_providers {
+    aws = {
+      source  = "hashicorp/aws"
+      version = "~> 4.0"
+    }
+    random = "~> 3.0"
+  }
+}
+
+provider "aws" {
+  alias = "east"
+}
+
+resource "aws_instance" "web" {
+}
+
+variable "instance_count" {
+  type        = number
+  description = "Number of instances"
+  default     = 2
+
+  validation {
+    condition     = var.instance_count > 0
+    error_message = "instance_count must be positive."
+  }
+}
+
+variable "tags" {
+  type     = map(string)
+  sensitive = true
+  nullable  = false
+}
+
+output "web_id" {
+  description = "The id of the web instance"
+}
+`)
+
+	doc, err := CreateHCL2(dir, SortByName)
+	if err != nil {
+		t.Fatalf("CreateHCL2 returned error: %s", err)
+	}
+
+	if doc.Version != ">= 0.12" {
+		t.Errorf("expected version %q, got %q", ">= 0.12", doc.Version)
+	}
+
+	if len(doc.Providers) != 2 {
+		t.Fatalf("expected 2 providers, got %d: %+v", len(doc.Providers), doc.Providers)
+	}
+	byName := map[string]Provider{}
+	for _, p := range doc.Providers {
+		byName[p.Name] = p
+	}
+
+	aws := byName["aws"]
+	if aws.Alias != "east" {
+		t.Errorf("expected aws alias %q, got %q", "east", aws.Alias)
+	}
+	if aws.Source != "hashicorp/aws" {
+		t.Errorf("expected aws source %q, got %q", "hashicorp/aws", aws.Source)
+	}
+	if aws.Documentation != "https://registry.terraform.io/providers/hashicorp/aws" {
+		t.Errorf("unexpected aws documentation link: %s", aws.Documentation)
+	}
+
+	random := byName["random"]
+	if random.Version != "~> 3.0" {
+		t.Errorf("expected random version %q, got %q", "~> 3.0", random.Version)
+	}
+	if random.Documentation != "https://www.terraform.io/docs/providers/random" {
+		t.Errorf("unexpected random documentation link: %s", random.Documentation)
+	}
+
+	if len(doc.Resources) != 1 || doc.Resources[0].Type != "aws_instance" {
+		t.Fatalf("unexpected resources: %+v", doc.Resources)
+	}
+
+	if len(doc.Inputs) != 2 {
+		t.Fatalf("expected 2 inputs, got %d: %+v", len(doc.Inputs), doc.Inputs)
+	}
+
+	count := doc.Inputs[0]
+	if count.Name != "instance_count" {
+		t.Fatalf("expected inputs sorted by name, got %+v", doc.Inputs)
+	}
+	if count.Type != "number" {
+		t.Errorf("expected type %q, got %q", "number", count.Type)
+	}
+	if count.Default == nil || count.Default.Literal != "2" {
+		t.Errorf("unexpected default: %+v", count.Default)
+	}
+	if len(count.Validations) != 1 || count.Validations[0].ErrorMessage != "instance_count must be positive." {
+		t.Errorf("unexpected validations: %+v", count.Validations)
+	}
+
+	tags := doc.Inputs[1]
+	if tags.Type != "map(string)" {
+		t.Errorf("expected type %q, got %q", "map(string)", tags.Type)
+	}
+	if !tags.Sensitive {
+		t.Error("expected tags to be sensitive")
+	}
+	if tags.Nullable {
+		t.Error("expected tags to not be nullable")
+	}
+
+	if len(doc.Outputs) != 1 || doc.Outputs[0].Name != "web_id" {
+		t.Fatalf("unexpected outputs: %+v", doc.Outputs)
+	}
+}
+
+func TestDetectMode(t *testing.T) {
+	hcl1Dir := t.TempDir()
+	writeFile(t, hcl1Dir+"/main.tf", `
+variable "name" {
+  type = "string"
+}
+`)
+	if mode := DetectMode(hcl1Dir); mode != ModeHCL1 {
+		t.Errorf("expected ModeHCL1, got %v", mode)
+	}
+
+	hcl2Dir := t.TempDir()
+	writeFile(t, hcl2Dir+"/main.tf", `
+variable "tags" {
+  type = map(string)
+}
+`)
+	if mode := DetectMode(hcl2Dir); mode != ModeHCL2 {
+		t.Errorf("expected ModeHCL2, got %v", mode)
+	}
+}
@@ -0,0 +1,56 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Format selects the output encoding a Doc is rendered to.
+type Format string
+
+// Supported output formats. FormatMarkdown is the default, human-readable
+// rendering; FormatJSON and FormatYAML emit the full Doc model so other
+// tools can consume it the way `terraform show -json` is consumed today.
+const (
+	FormatMarkdown Format = "markdown"
+	FormatJSON     Format = "json"
+	FormatYAML     Format = "yaml"
+)
+
+// String implements flag.Value, so Format can be passed to flag.Var once
+// this repository grows a cmd/main package to register it against a
+// --format flag; no such entrypoint exists yet, so nothing calls this today.
+func (f *Format) String() string {
+	if *f == "" {
+		return string(FormatMarkdown)
+	}
+
+	return string(*f)
+}
+
+// Set implements flag.Value. See the String doc comment above: this is
+// unused until a CLI entrypoint exists to register it.
+func (f *Format) Set(s string) error {
+	switch Format(s) {
+	case FormatMarkdown, FormatJSON, FormatYAML:
+		*f = Format(s)
+		return nil
+	default:
+		return fmt.Errorf("unknown format %q, expected markdown, json or yaml", s)
+	}
+}
+
+// MarshalJSON serializes doc as a stable JSON document describing the
+// full Doc model: providers (with source/version/alias), resources,
+// inputs (with validations) and outputs.
+func MarshalJSON(doc *Doc) ([]byte, error) {
+	return json.MarshalIndent(doc, "", "  ")
+}
+
+// MarshalYAML serializes doc as YAML using the same field names as
+// MarshalJSON.
+func MarshalYAML(doc *Doc) ([]byte, error) {
+	return yaml.Marshal(doc)
+}
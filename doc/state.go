@@ -0,0 +1,189 @@
+package doc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+	"strings"
+)
+
+// MergeState overlays the resolved output values and resource instance
+// counts recorded in the Terraform state file at statePath onto doc. It
+// supports both the flat pre-0.12 state format and the nested
+// `values.root_module` format used by 0.12+, auto-detecting which one it
+// is looking at from the state's shape alone: a top-level `modules` list
+// selects the pre-0.12 reader and a top-level `values` object the 0.12+
+// one. The top-level `version` key only appears in the error for an
+// unrecognized file, and `terraform_version` is not consulted.
+func MergeState(doc *Doc, statePath string) error {
+	raw, err := ioutil.ReadFile(statePath)
+	if err != nil {
+		return err
+	}
+
+	var probe struct {
+		Version          int             `json:"version"`
+		TerraformVersion string          `json:"terraform_version"`
+		Modules          json.RawMessage `json:"modules"`
+		Values           json.RawMessage `json:"values"`
+	}
+	if err := json.Unmarshal(raw, &probe); err != nil {
+		return fmt.Errorf("parsing %s: %s", statePath, err)
+	}
+
+	switch {
+	case probe.Modules != nil:
+		return mergeLegacyState(doc, probe.Modules)
+	case probe.Values != nil:
+		return mergeNestedState(doc, probe.Values)
+	default:
+		return fmt.Errorf("%s: unrecognized terraform state format (version %d)", statePath, probe.Version)
+	}
+}
+
+// Pre-0.12 (state format 1-3) shape: a flat list of modules, each with
+// its own outputs and a resources map keyed by "type.name" or, for
+// resources using `count`, "type.name.N".
+type legacyModule struct {
+	Outputs   map[string]legacyOutput   `json:"outputs"`
+	Resources map[string]legacyResource `json:"resources"`
+}
+
+type legacyOutput struct {
+	Sensitive bool        `json:"sensitive"`
+	Value     interface{} `json:"value"`
+}
+
+type legacyResource struct {
+	Type string `json:"type"`
+}
+
+func mergeLegacyState(doc *Doc, raw json.RawMessage) error {
+	var modules []legacyModule
+	if err := json.Unmarshal(raw, &modules); err != nil {
+		return err
+	}
+
+	outputs := map[string]legacyOutput{}
+	instances := map[string]int{}
+
+	for _, m := range modules {
+		for name, o := range m.Outputs {
+			outputs[name] = o
+		}
+
+		for key := range m.Resources {
+			base := key
+			if idx := strings.LastIndex(key, "."); idx >= 0 {
+				if _, err := strconv.Atoi(key[idx+1:]); err == nil {
+					base = key[:idx]
+				}
+			}
+			instances[base]++
+		}
+	}
+
+	mergeOutputs(doc, outputs)
+	mergeResourceInstances(doc, instances)
+
+	return nil
+}
+
+// 0.12+ shape, as produced by `terraform show -json`: outputs live under
+// `values.outputs`, and resources (one entry per resource instance,
+// already expanded over `count`/`for_each`) under `values.root_module`,
+// with nested modules under `child_modules`.
+type nestedValues struct {
+	Outputs    map[string]nestedOutput `json:"outputs"`
+	RootModule nestedModule            `json:"root_module"`
+}
+
+type nestedOutput struct {
+	Value     interface{} `json:"value"`
+	Sensitive bool        `json:"sensitive"`
+}
+
+type nestedModule struct {
+	Resources    []nestedResource `json:"resources"`
+	ChildModules []nestedModule   `json:"child_modules"`
+}
+
+type nestedResource struct {
+	Address string `json:"address"`
+	Type    string `json:"type"`
+	Name    string `json:"name"`
+}
+
+func mergeNestedState(doc *Doc, raw json.RawMessage) error {
+	var v nestedValues
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return err
+	}
+
+	outputs := map[string]legacyOutput{}
+	for name, o := range v.Outputs {
+		outputs[name] = legacyOutput{Sensitive: o.Sensitive, Value: o.Value}
+	}
+
+	instances := map[string]int{}
+	addresses := map[string][]string{}
+	collectNestedInstances(v.RootModule, instances, addresses)
+
+	mergeOutputs(doc, outputs)
+	mergeResourceInstances(doc, instances)
+	mergeResourceAddresses(doc, addresses)
+
+	return nil
+}
+
+// collectNestedInstances walks a module (and its children) tallying
+// instance counts and example addresses per "type.name" resource. Each
+// entry in a module's `resources` list is already expanded to one
+// per-instance address by Terraform, so both numeric count indices
+// (`foo[0]`) and string for_each indices (`foo["key"]`) fall out of
+// r.Address for free.
+func collectNestedInstances(m nestedModule, instances map[string]int, addresses map[string][]string) {
+	for _, r := range m.Resources {
+		base := r.Type + "." + r.Name
+		instances[base]++
+		addresses[base] = append(addresses[base], r.Address)
+	}
+
+	for _, child := range m.ChildModules {
+		collectNestedInstances(child, instances, addresses)
+	}
+}
+
+// mergeOutputs overlays resolved output values/sensitivity from state
+// onto doc.Outputs, matching by name.
+func mergeOutputs(doc *Doc, outputs map[string]legacyOutput) {
+	for i, o := range doc.Outputs {
+		if state, ok := outputs[o.Name]; ok {
+			doc.Outputs[i].Value = state.Value
+			doc.Outputs[i].Sensitive = state.Sensitive
+		}
+	}
+}
+
+// mergeResourceInstances overlays instance counts from state onto
+// doc.Resources, matching by "type.name".
+func mergeResourceInstances(doc *Doc, instances map[string]int) {
+	for i, r := range doc.Resources {
+		base := r.Type + "." + r.Name
+		if n, ok := instances[base]; ok {
+			doc.Resources[i].Instances = n
+		}
+	}
+}
+
+// mergeResourceAddresses overlays example instance addresses from state
+// onto doc.Resources, matching by "type.name".
+func mergeResourceAddresses(doc *Doc, addresses map[string][]string) {
+	for i, r := range doc.Resources {
+		base := r.Type + "." + r.Name
+		if addrs, ok := addresses[base]; ok {
+			doc.Resources[i].Addresses = addrs
+		}
+	}
+}
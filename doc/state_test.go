@@ -0,0 +1,106 @@
+package doc
+
+import "testing"
+
+func newTestDoc() *Doc {
+	return &Doc{
+		Resources: []Resource{
+			{Name: "web", Type: "aws_instance"},
+		},
+		Outputs: []Output{
+			{Name: "id"},
+			{Name: "secret"},
+		},
+	}
+}
+
+func TestMergeStateLegacy(t *testing.T) {
+	path := t.TempDir() + "/terraform.tfstate"
+	writeFile(t, path, `{
+  "version": 3,
+  "terraform_version": "0.11.14",
+  "modules": [
+    {
+      "path": ["root"],
+      "outputs": {
+        "id": {"sensitive": false, "value": "i-abc123"},
+        "secret": {"sensitive": true, "value": "shh"}
+      },
+      "resources": {
+        "aws_instance.web.0": {"type": "aws_instance"},
+        "aws_instance.web.1": {"type": "aws_instance"}
+      }
+    }
+  ]
+}`)
+
+	doc := newTestDoc()
+	if err := MergeState(doc, path); err != nil {
+		t.Fatalf("MergeState returned error: %s", err)
+	}
+
+	if doc.Resources[0].Instances != 2 {
+		t.Errorf("expected 2 instances, got %d", doc.Resources[0].Instances)
+	}
+
+	if doc.Outputs[0].Value != "i-abc123" {
+		t.Errorf("expected output id value %q, got %v", "i-abc123", doc.Outputs[0].Value)
+	}
+	if !doc.Outputs[1].Sensitive {
+		t.Error("expected output secret to be marked sensitive")
+	}
+}
+
+func TestMergeStateNested(t *testing.T) {
+	path := t.TempDir() + "/terraform.tfstate"
+	writeFile(t, path, `{
+  "version": 4,
+  "terraform_version": "1.5.0",
+  "values": {
+    "outputs": {
+      "id": {"value": "i-abc123", "sensitive": false},
+      "secret": {"value": "shh", "sensitive": true}
+    },
+    "root_module": {
+      "resources": [
+        {"address": "aws_instance.web[0]", "type": "aws_instance", "name": "web"},
+        {"address": "aws_instance.web[1]", "type": "aws_instance", "name": "web"}
+      ],
+      "child_modules": [
+        {
+          "resources": [
+            {"address": "module.net.aws_instance.web[\"east\"]", "type": "aws_instance", "name": "web"}
+          ]
+        }
+      ]
+    }
+  }
+}`)
+
+	doc := newTestDoc()
+	if err := MergeState(doc, path); err != nil {
+		t.Fatalf("MergeState returned error: %s", err)
+	}
+
+	if doc.Resources[0].Instances != 3 {
+		t.Errorf("expected 3 instances, got %d", doc.Resources[0].Instances)
+	}
+
+	want := []string{"aws_instance.web[0]", "aws_instance.web[1]", `module.net.aws_instance.web["east"]`}
+	got := doc.Resources[0].Addresses
+	if len(got) != len(want) {
+		t.Fatalf("expected addresses %v, got %v", want, got)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("expected address %q at index %d, got %q", want[i], i, got[i])
+		}
+	}
+
+	if doc.Outputs[0].Value != "i-abc123" {
+		t.Errorf("expected output id value %q, got %v", "i-abc123", doc.Outputs[0].Value)
+	}
+	if !doc.Outputs[1].Sensitive {
+		t.Error("expected output secret to be marked sensitive")
+	}
+}
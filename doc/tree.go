@@ -0,0 +1,165 @@
+package doc
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ModuleTree represents a Terraform project made up of a root module and
+// the child modules it calls via local `module "x" { source = "./child" }`
+// blocks, mirroring how real projects nest reusable modules under a
+// parent directory.
+type ModuleTree struct {
+	Path     string
+	Doc      *Doc
+	Children []*ModuleTree
+}
+
+// CreateTree walks the directory tree rooted at root, building a *Doc for
+// every directory that contains Terraform configuration (skipping
+// .terraform and other hidden directories), and resolves local `module`
+// blocks into parent->child edges between those directories.
+func CreateTree(root string, mode SortMode) (*ModuleTree, error) {
+	docs := map[string]*Doc{}
+	edges := map[string][]string{}
+
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			return nil
+		}
+
+		if path != root && skipDir(info.Name()) {
+			return filepath.SkipDir
+		}
+
+		tfFiles, err := filepath.Glob(filepath.Join(path, "*.tf"))
+		if err != nil {
+			return err
+		}
+		if len(tfFiles) == 0 {
+			return nil
+		}
+
+		d, err := CreateDir(path, mode)
+		if err != nil {
+			return err
+		}
+		docs[path] = d
+
+		sources, err := moduleSourcesDir(path)
+		if err != nil {
+			return err
+		}
+
+		for _, src := range sources {
+			if !isLocalModuleSource(src) {
+				continue
+			}
+
+			edges[path] = append(edges[path], filepath.Clean(filepath.Join(path, src)))
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return buildTree(root, docs, edges)
+}
+
+// buildTree assembles the ModuleTree rooted at root from the flat set of
+// discovered docs and parent->child edges. Any directory CreateTree
+// parsed into docs but that the root never reaches through a `module`
+// block — a folder of reusable submodules nobody locally calls, or a
+// group of modules that only reference each other — is attached as an
+// extra top-level child instead of being silently dropped, since the
+// work to parse it has already been done.
+func buildTree(root string, docs map[string]*Doc, edges map[string][]string) (*ModuleTree, error) {
+	visited := map[string]bool{}
+
+	node, err := buildTreeNode(root, docs, edges, map[string]bool{}, visited)
+	if err != nil {
+		return nil, err
+	}
+
+	var orphans []string
+	for dir := range docs {
+		if !visited[dir] {
+			orphans = append(orphans, dir)
+		}
+	}
+	sort.Strings(orphans)
+
+	for _, dir := range orphans {
+		if visited[dir] {
+			// Reached by an earlier orphan's own module references.
+			continue
+		}
+
+		child, err := buildTreeNode(dir, docs, edges, map[string]bool{}, visited)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Children = append(node.Children, child)
+	}
+
+	return node, nil
+}
+
+// buildTreeNode assembles the ModuleTree rooted at dir. onStack tracks
+// the current recursion path so a `module` reference cycle (directly
+// self-referencing, or two modules pointing at each other) is reported
+// as an error instead of recursing forever. visited tracks every
+// directory attached to the tree so far so a module reused from more
+// than one place is only attached once, at the place it was first
+// reached.
+func buildTreeNode(dir string, docs map[string]*Doc, edges map[string][]string, onStack, visited map[string]bool) (*ModuleTree, error) {
+	onStack[dir] = true
+	visited[dir] = true
+	defer delete(onStack, dir)
+
+	node := &ModuleTree{Path: dir, Doc: docs[dir]}
+
+	for _, child := range edges[dir] {
+		if onStack[child] {
+			return nil, fmt.Errorf("circular module reference detected: %s -> %s", dir, child)
+		}
+		if visited[child] {
+			// Reused from elsewhere in the tree; attach it only at
+			// the place it was first reached.
+			continue
+		}
+
+		childNode, err := buildTreeNode(child, docs, edges, onStack, visited)
+		if err != nil {
+			return nil, err
+		}
+
+		node.Children = append(node.Children, childNode)
+	}
+
+	return node, nil
+}
+
+// isLocalModuleSource reports whether src addresses a module on the
+// local filesystem (./ or ../), as opposed to a registry or remote
+// source, which CreateTree does not follow.
+func isLocalModuleSource(src string) bool {
+	return strings.HasPrefix(src, "./") || strings.HasPrefix(src, "../")
+}
+
+// skipDir reports whether a directory should be excluded from module
+// discovery: the Terraform working-directory cache and any hidden
+// directory.
+func skipDir(name string) bool {
+	return name == ".terraform" || strings.HasPrefix(name, ".")
+}
@@ -0,0 +1,150 @@
+package doc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeFile(t *testing.T, path, contents string) {
+	t.Helper()
+
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCreateTree(t *testing.T) {
+	root := t.TempDir()
+	child := filepath.Join(root, "modules", "child")
+	if err := os.MkdirAll(child, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(root, "main.tf"), `
+module "child" {
+  source = "./modules/child"
+}
+
+variable "region" {
+  type = string
+}
+`)
+
+	writeFile(t, filepath.Join(child, "main.tf"), `
+variable "name" {
+  type = string
+}
+
+output "id" {
+  value = "child-id"
+}
+`)
+
+	tree, err := CreateTree(root, SortByName)
+	if err != nil {
+		t.Fatalf("CreateTree returned error: %s", err)
+	}
+
+	if tree.Path != root {
+		t.Fatalf("expected root node path %q, got %q", root, tree.Path)
+	}
+	if len(tree.Doc.Inputs) != 1 || tree.Doc.Inputs[0].Name != "region" {
+		t.Fatalf("unexpected root inputs: %+v", tree.Doc.Inputs)
+	}
+
+	if len(tree.Children) != 1 {
+		t.Fatalf("expected 1 child module, got %d", len(tree.Children))
+	}
+
+	childNode := tree.Children[0]
+	if childNode.Path != child {
+		t.Fatalf("expected child node path %q, got %q", child, childNode.Path)
+	}
+	if len(childNode.Doc.Inputs) != 1 || childNode.Doc.Inputs[0].Name != "name" {
+		t.Fatalf("unexpected child inputs: %+v", childNode.Doc.Inputs)
+	}
+	if len(childNode.Doc.Outputs) != 1 || childNode.Doc.Outputs[0].Name != "id" {
+		t.Fatalf("unexpected child outputs: %+v", childNode.Doc.Outputs)
+	}
+}
+
+func TestCreateTreeSelfReferencingModuleErrors(t *testing.T) {
+	root := t.TempDir()
+
+	writeFile(t, filepath.Join(root, "main.tf"), `
+module "self" {
+  source = "./"
+}
+`)
+
+	if _, err := CreateTree(root, SortByName); err == nil {
+		t.Fatal("expected an error for a self-referencing module, got nil")
+	}
+}
+
+func TestCreateTreeMutuallyReferencingModulesError(t *testing.T) {
+	root := t.TempDir()
+	a := filepath.Join(root, "a")
+	b := filepath.Join(root, "b")
+	if err := os.MkdirAll(a, 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(b, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(root, "main.tf"), `
+module "a" {
+  source = "./a"
+}
+`)
+	writeFile(t, filepath.Join(a, "main.tf"), `
+module "b" {
+  source = "../b"
+}
+`)
+	writeFile(t, filepath.Join(b, "main.tf"), `
+module "a" {
+  source = "../a"
+}
+`)
+
+	if _, err := CreateTree(root, SortByName); err == nil {
+		t.Fatal("expected an error for mutually referencing modules, got nil")
+	}
+}
+
+func TestCreateTreeAttachesUnreferencedModules(t *testing.T) {
+	root := t.TempDir()
+	orphan := filepath.Join(root, "modules", "orphan")
+	if err := os.MkdirAll(orphan, 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	writeFile(t, filepath.Join(root, "main.tf"), `
+variable "region" {
+  type = string
+}
+`)
+	writeFile(t, filepath.Join(orphan, "main.tf"), `
+variable "name" {
+  type = string
+}
+`)
+
+	tree, err := CreateTree(root, SortByName)
+	if err != nil {
+		t.Fatalf("CreateTree returned error: %s", err)
+	}
+
+	if len(tree.Children) != 1 {
+		t.Fatalf("expected the unreferenced orphan module to be attached as a child, got %d children", len(tree.Children))
+	}
+	if tree.Children[0].Path != orphan {
+		t.Fatalf("expected orphan child path %q, got %q", orphan, tree.Children[0].Path)
+	}
+	if len(tree.Children[0].Doc.Inputs) != 1 || tree.Children[0].Doc.Inputs[0].Name != "name" {
+		t.Fatalf("unexpected orphan inputs: %+v", tree.Children[0].Doc.Inputs)
+	}
+}